@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Formatter renders a LogRecord to an io.Writer in a particular output
+// format, selected via --format.
+type Formatter interface {
+	Format(w io.Writer, rec *LogRecord) error
+}
+
+// NewFormatter returns the Formatter for the given --format value.
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case "", "text":
+		return textFormatter{}, nil
+	case "json":
+		return jsonFormatter{}, nil
+	case "logfmt":
+		return logfmtFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// textFormatter reproduces rdslogs' original behaviour: the raw log line,
+// unmodified.
+type textFormatter struct{}
+
+func (textFormatter) Format(w io.Writer, rec *LogRecord) error {
+	_, err := fmt.Fprintln(w, rec.Raw)
+	return err
+}
+
+// jsonFormatter emits one newline-delimited JSON object per record.
+type jsonFormatter struct{}
+
+type jsonLogRecord struct {
+	Timestamp string            `json:"timestamp,omitempty"`
+	Severity  string            `json:"severity,omitempty"`
+	PID       string            `json:"pid,omitempty"`
+	Database  string            `json:"database,omitempty"`
+	Engine    string            `json:"engine,omitempty"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+func (jsonFormatter) Format(w io.Writer, rec *LogRecord) error {
+	out := jsonLogRecord{
+		Severity: rec.Severity,
+		PID:      rec.PID,
+		Database: rec.Database,
+		Engine:   rec.Engine,
+		Message:  rec.Message,
+		Fields:   rec.Fields,
+	}
+	if !rec.Timestamp.IsZero() {
+		out.Timestamp = rec.Timestamp.UTC().Format("2006-01-02T15:04:05.999999Z")
+	}
+	enc := json.NewEncoder(w)
+	return enc.Encode(out)
+}
+
+// logfmtFormatter emits key=value pairs, one record per line.
+type logfmtFormatter struct{}
+
+func (logfmtFormatter) Format(w io.Writer, rec *LogRecord) error {
+	var b strings.Builder
+	if !rec.Timestamp.IsZero() {
+		fmt.Fprintf(&b, "timestamp=%s ", rec.Timestamp.UTC().Format("2006-01-02T15:04:05.999999Z"))
+	}
+	if rec.Engine != "" {
+		fmt.Fprintf(&b, "engine=%s ", quoteIfNeeded(rec.Engine))
+	}
+	if rec.Severity != "" {
+		fmt.Fprintf(&b, "severity=%s ", quoteIfNeeded(rec.Severity))
+	}
+	if rec.PID != "" {
+		fmt.Fprintf(&b, "pid=%s ", quoteIfNeeded(rec.PID))
+	}
+	if rec.Database != "" {
+		fmt.Fprintf(&b, "database=%s ", quoteIfNeeded(rec.Database))
+	}
+
+	keys := make([]string, 0, len(rec.Fields))
+	for k := range rec.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s ", k, quoteIfNeeded(rec.Fields[k]))
+	}
+
+	fmt.Fprintf(&b, "message=%s", quoteIfNeeded(rec.Message))
+
+	_, err := fmt.Fprintln(w, b.String())
+	return err
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogRecord is the normalized representation of a single RDS log entry,
+// regardless of which engine produced it. Fields is used for engine-specific
+// extras (e.g. slow-query duration for MySQL, SQLSTATE for Postgres) that
+// don't warrant a dedicated struct field.
+type LogRecord struct {
+	Timestamp time.Time
+	Severity  string
+	PID       string
+	Database  string
+	Message   string
+	Engine    string
+	Raw       string
+	File      string
+	Fields    map[string]string
+}
+
+// LogParser turns raw RDS log lines into LogRecords. Engines that emit
+// multi-line entries (MySQL slow-query blocks, Postgres LOG:/STATEMENT:
+// continuations) buffer internally and only return a record once it's
+// known to be complete.
+type LogParser interface {
+	// Feed consumes one line of input. It returns a non-nil record when
+	// feeding the line completed a previously buffered entry.
+	Feed(line string) (*LogRecord, error)
+	// Flush returns any record still buffered (e.g. at EOF) or nil.
+	Flush() *LogRecord
+}
+
+// NewLogParser returns the LogParser for the given RDS engine.
+func NewLogParser(engine string) (LogParser, error) {
+	switch engine {
+	case "mysql", "mariadb":
+		return &mysqlLogParser{engine: engine}, nil
+	case "postgres":
+		return &postgresLogParser{engine: engine}, nil
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", engine)
+	}
+}
+
+var (
+	mysqlTimeLine     = regexp.MustCompile(`^# Time:\s*(\S+)`)
+	mysqlUserHostLine = regexp.MustCompile(`^# User@Host:\s*(\S+)`)
+	mysqlStatsLine    = regexp.MustCompile(`^# Query_time:\s*(\S+)\s+Lock_time:\s*(\S+)\s+Rows_sent:\s*(\S+)\s+Rows_examined:\s*(\S+)`)
+	mysqlGeneralLine  = regexp.MustCompile(`^(\S+)\s+(\d+)\s+\[(\w+)\]\s*(.*)$`)
+)
+
+// mysqlLogParser coalesces MySQL/MariaDB slow-query blocks (the "# Time:"
+// header plus the SQL body that follows) into a single LogRecord. Lines that
+// don't belong to a slow-query block are parsed as plain general/error log
+// entries and returned immediately.
+type mysqlLogParser struct {
+	engine string
+	buf    *LogRecord
+	raw    []string
+	body   []string
+}
+
+func (p *mysqlLogParser) Feed(line string) (*LogRecord, error) {
+	if m := mysqlTimeLine.FindStringSubmatch(line); m != nil {
+		flushed := p.Flush()
+		ts, err := time.Parse("2006-01-02T15:04:05.999999Z", m[1])
+		if err != nil {
+			ts = time.Time{}
+		}
+		p.buf = &LogRecord{
+			Timestamp: ts,
+			Engine:    p.engine,
+			Fields:    make(map[string]string),
+		}
+		p.raw = []string{line}
+		p.body = nil
+		return flushed, nil
+	}
+
+	if p.buf != nil {
+		p.raw = append(p.raw, line)
+		if m := mysqlUserHostLine.FindStringSubmatch(line); m != nil {
+			p.buf.Fields["user_host"] = strings.TrimSpace(strings.TrimPrefix(line, "# User@Host:"))
+			return nil, nil
+		}
+		if m := mysqlStatsLine.FindStringSubmatch(line); m != nil {
+			p.buf.Fields["query_time"] = m[1]
+			p.buf.Fields["lock_time"] = m[2]
+			p.buf.Fields["rows_sent"] = m[3]
+			p.buf.Fields["rows_examined"] = m[4]
+			return nil, nil
+		}
+		if strings.HasPrefix(line, "#") {
+			// Unrecognized comment line belonging to the block; ignore it.
+			return nil, nil
+		}
+		p.body = append(p.body, line)
+		return nil, nil
+	}
+
+	if m := mysqlGeneralLine.FindStringSubmatch(line); m != nil {
+		ts, err := time.Parse("2006-01-02T15:04:05.999999Z", m[1])
+		if err != nil {
+			return nil, err
+		}
+		return &LogRecord{
+			Timestamp: ts,
+			Severity:  strings.ToUpper(m[3]),
+			PID:       m[2],
+			Engine:    p.engine,
+			Message:   m[4],
+			Raw:       line,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid format")
+}
+
+func (p *mysqlLogParser) Flush() *LogRecord {
+	if p.buf == nil {
+		return nil
+	}
+	rec := p.buf
+	rec.Message = strings.TrimSpace(strings.Join(p.body, "\n"))
+	rec.Raw = strings.Join(p.raw, "\n")
+	p.buf = nil
+	p.raw = nil
+	p.body = nil
+	return rec
+}
+
+var postgresLogLine = regexp.MustCompile(`^(\S+ \S+) UTC:([^(]*)\(([^)]*)\):([^:]*)@([^:]*):\[(\d+)\]:(\w+):\s?(.*)$`)
+
+// postgresLogParser coalesces a Postgres "LOG:" entry together with any
+// "STATEMENT:" (or other unprefixed) continuation lines that follow it into
+// a single LogRecord, mirroring how Postgres itself splits a single
+// statement error across lines.
+type postgresLogParser struct {
+	engine string
+	buf    *LogRecord
+}
+
+func (p *postgresLogParser) Feed(line string) (*LogRecord, error) {
+	m := postgresLogLine.FindStringSubmatch(line)
+	if m == nil {
+		if p.buf != nil {
+			p.buf.Message += "\n" + strings.TrimSpace(line)
+			p.buf.Raw += "\n" + line
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid format")
+	}
+
+	flushed := p.Flush()
+
+	ts, err := time.Parse("2006-01-02 15:04:05", m[1])
+	if err != nil {
+		return flushed, err
+	}
+
+	p.buf = &LogRecord{
+		Timestamp: ts,
+		Severity:  m[7],
+		PID:       m[6],
+		Database:  m[5],
+		Engine:    p.engine,
+		Message:   m[8],
+		Raw:       line,
+		Fields: map[string]string{
+			"user": m[4],
+			"host": m[2],
+		},
+	}
+
+	if sqlstate := sqlstateFromMessage(m[8]); sqlstate != "" {
+		p.buf.Fields["sqlstate"] = sqlstate
+	}
+
+	return flushed, nil
+}
+
+func (p *postgresLogParser) Flush() *LogRecord {
+	if p.buf == nil {
+		return nil
+	}
+	rec := p.buf
+	p.buf = nil
+	return rec
+}
+
+var sqlstateRegexp = regexp.MustCompile(`SQLSTATE\[(\w+)\]`)
+
+func sqlstateFromMessage(msg string) string {
+	if m := sqlstateRegexp.FindStringSubmatch(msg); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// lineAfter reports whether a record's timestamp is after filterTime, used
+// to implement --since filtering uniformly across engines.
+func lineAfter(rec *LogRecord, filterTime time.Time, sinceSet bool) bool {
+	if !sinceSet {
+		return true
+	}
+	return rec.Timestamp.After(filterTime)
+}
+
+// quoteIfNeeded is a small logfmt helper shared by formatter.go.
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
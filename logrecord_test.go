@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMysqlLogParserGeneralLine(t *testing.T) {
+	p, err := NewLogParser("mysql")
+	if err != nil {
+		t.Fatalf("NewLogParser: %v", err)
+	}
+
+	rec, err := p.Feed("2023-01-02T03:04:05.000000Z 123 [Note] mysqld: ready for connections")
+	if err != nil {
+		t.Fatalf("Feed: %v", err)
+	}
+	if rec == nil {
+		t.Fatal("expected a record, got nil")
+	}
+	if rec.Severity != "NOTE" || rec.PID != "123" || rec.Message != "mysqld: ready for connections" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	wantTS := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !rec.Timestamp.Equal(wantTS) {
+		t.Errorf("Timestamp = %v, want %v", rec.Timestamp, wantTS)
+	}
+}
+
+func TestMysqlLogParserSlowQueryBlock(t *testing.T) {
+	p, err := NewLogParser("mysql")
+	if err != nil {
+		t.Fatalf("NewLogParser: %v", err)
+	}
+
+	lines := []string{
+		"# Time: 2023-01-02T03:04:05.000000Z",
+		"# User@Host: root[root] @  localhost []",
+		"# Query_time: 1.500000  Lock_time: 0.000100 Rows_sent: 1  Rows_examined: 10",
+		"SELECT 1;",
+	}
+	for _, line := range lines {
+		rec, err := p.Feed(line)
+		if err != nil {
+			t.Fatalf("Feed(%q): %v", line, err)
+		}
+		if rec != nil {
+			t.Fatalf("Feed(%q) returned a record before the block ended: %+v", line, rec)
+		}
+	}
+
+	rec := p.Flush()
+	if rec == nil {
+		t.Fatal("Flush returned nil, want the buffered slow-query record")
+	}
+	if rec.Message != "SELECT 1;" {
+		t.Errorf("Message = %q, want %q", rec.Message, "SELECT 1;")
+	}
+	if rec.Fields["query_time"] != "1.500000" || rec.Fields["rows_examined"] != "10" {
+		t.Errorf("unexpected fields: %+v", rec.Fields)
+	}
+}
+
+func TestPostgresLogParserContinuation(t *testing.T) {
+	p, err := NewLogParser("postgres")
+	if err != nil {
+		t.Fatalf("NewLogParser: %v", err)
+	}
+
+	line := "2023-01-02 03:04:05 UTC:10.0.0.1(12345):myuser@mydb:[42]:LOG: duration: 12.3 ms"
+	if rec, err := p.Feed(line); err != nil || rec != nil {
+		t.Fatalf("Feed(%q) = %v, %v; want nil, nil", line, rec, err)
+	}
+
+	if rec, err := p.Feed("STATEMENT:  select 1"); err != nil || rec != nil {
+		t.Fatalf("Feed(continuation) = %v, %v; want nil, nil", rec, err)
+	}
+
+	rec := p.Flush()
+	if rec == nil {
+		t.Fatal("Flush returned nil, want the buffered record")
+	}
+	if rec.Database != "mydb" || rec.PID != "42" || rec.Severity != "LOG" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+	if rec.Message != "duration: 12.3 ms\nSTATEMENT:  select 1" {
+		t.Errorf("Message = %q", rec.Message)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -14,22 +13,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 )
 
-func parseLogTime(line string, engine string) (time.Time, error) {
-	switch engine {
-	case "mysql", "mariadb":
-		parts := strings.Split(line, " ")
-		if len(parts) < 1 {
-			return time.Time{}, fmt.Errorf("invalid format")
-		}
-		return time.Parse("2006-01-02T15:04:05.999999Z", parts[0])
-	case "postgres":
-		timeStr := strings.Split(line, " UTC")[0]
-		return time.Parse("2006-01-02 15:04:05", timeStr)
-	default:
-		return time.Time{}, fmt.Errorf("unsupported engine: %s", engine)
-	}
-}
-
 func getDBEngine(ctx context.Context, client *rds.Client, identifier string) (string, error) {
 	input := &rds.DescribeDBInstancesInput{
 		DBInstanceIdentifier: &identifier,
@@ -49,6 +32,17 @@ func main() {
 	since := flag.String("since", "", "Start from logs after this timestamp (format: 2006-01-02 15:04:05) or duration (1h, 5m)")
 	follow := flag.Bool("f", false, "Follow log output")
 	flag.BoolVar(follow, "follow", false, "Follow log output")
+	format := flag.String("format", "text", "Output format: text, json, or logfmt")
+	summary := flag.Bool("summary", false, "Summarize MySQL/MariaDB slow query log entries instead of streaming them")
+	summaryTop := flag.Int("summary-top", 10, "Number of top query fingerprints to report in --summary mode")
+	summarySort := flag.String("summary-sort", "time", "Sort --summary report by: time, count, or rows")
+	summaryInterval := flag.Duration("summary-interval", 10*time.Second, "How often to print the --summary report while following (-f)")
+	concurrency := flag.Int("concurrency", 1, "Number of log files to download concurrently")
+	stateFile := flag.String("state-file", "", "Path to a JSON checkpoint file used to resume -f across restarts")
+	sinkFlag := flag.String("sink", "stdout", "Where to write log output: stdout, file://path, s3://bucket/prefix, cloudwatch://group/stream, or kafka://broker/topic")
+	metricsAddr := flag.String("metrics-addr", "", "Address to expose Prometheus metrics and /healthz on (e.g. :9090); only used with -f")
+	minPollInterval := flag.Duration("min-poll-interval", 2*time.Second, "Poll interval used in follow mode as soon as new data arrives")
+	maxPollInterval := flag.Duration("max-poll-interval", 60*time.Second, "Poll interval follow mode backs off to after consecutive empty polls")
 	flag.Parse()
 
 	if *instance == "" {
@@ -56,6 +50,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	formatter, err := NewFormatter(*format)
+	if err != nil {
+		fmt.Printf("Invalid --format: %v\n", err)
+		os.Exit(1)
+	}
+
+	sink, err := NewSink(*sinkFlag, formatter)
+	if err != nil {
+		fmt.Printf("Invalid --sink: %v\n", err)
+		os.Exit(1)
+	}
+	defer sink.Close()
+
 	cfg, err := config.LoadDefaultConfig(context.Background())
 	if err != nil {
 		fmt.Printf("Unable to load SDK config: %v\n", err)
@@ -71,7 +78,28 @@ func main() {
 		os.Exit(1)
 	}
 
-	lastMarkers := make(map[string]string)
+	var aggregator *SlowLogAggregator
+	if *summary {
+		if engine != "mysql" && engine != "mariadb" {
+			fmt.Printf("--summary is only supported for mysql/mariadb, got engine %q\n", engine)
+			os.Exit(1)
+		}
+		aggregator = NewSlowLogAggregator()
+	}
+	var lastSummaryPrinted time.Time
+
+	markers, err := newMarkerStore(*stateFile)
+	if err != nil {
+		fmt.Printf("Failed to load state file: %v\n", err)
+		os.Exit(1)
+	}
+
+	scheduler := newPollScheduler(*minPollInterval, *maxPollInterval)
+	health := newHealthTracker(*maxPollInterval)
+	if *follow && *metricsAddr != "" {
+		startMetricsServer(*metricsAddr, health)
+	}
+
 	var filterTime time.Time
 	if *since != "" {
 		if d, err := time.ParseDuration(*since); err == nil {
@@ -97,70 +125,76 @@ func main() {
 			input.FileLastWritten = aws.Int64(filterTime.UnixMilli())
 		}
 
-		output, err := client.DescribeDBLogFiles(ctx, input)
+		var output *rds.DescribeDBLogFilesOutput
+		err = retryOnThrottle(func() error {
+			awsAPICallsTotal.WithLabelValues("DescribeDBLogFiles").Inc()
+			var callErr error
+			output, callErr = client.DescribeDBLogFiles(ctx, input)
+			return callErr
+		})
 		if err != nil {
+			downloadErrorsTotal.WithLabelValues(errorCode(err)).Inc()
 			fmt.Printf("Failed to describe log files: %v\n", err)
 			os.Exit(1)
 		}
+		health.recordPollSuccess()
 
 		sort.Slice(output.DescribeDBLogFiles, func(i, j int) bool {
 			return *output.DescribeDBLogFiles[i].LastWritten < *output.DescribeDBLogFiles[j].LastWritten
 		})
 
 		for _, file := range output.DescribeDBLogFiles {
-			downloadInput := &rds.DownloadDBLogFilePortionInput{
-				DBInstanceIdentifier: instance,
-				LogFileName:          file.LogFileName,
-			}
-
-			if marker := lastMarkers[*file.LogFileName]; marker != "" {
-				downloadInput.Marker = &marker
-			}
-
-			var lastMarker string
-			for {
-				portion, err := client.DownloadDBLogFilePortion(ctx, downloadInput)
-				if err != nil {
-					fmt.Printf("Failed to download log portion: %v\n", err)
-					break
-				}
-
-				if portion.LogFileData != nil {
-					lines := strings.Split(*portion.LogFileData, "\n")
-					for _, line := range lines {
-						if strings.TrimSpace(line) == "" {
-							continue
-						}
-						lineTime, err := parseLogTime(line, engine)
-						if err != nil {
-							fmt.Print(line + "\n")
-							continue
-						}
-						if *since == "" || lineTime.After(filterTime) {
-							fmt.Print(line + "\n")
-						}
-					}
-				}
-
-				if portion.Marker != nil {
-					lastMarker = *portion.Marker
-					downloadInput.Marker = &lastMarker
-				}
+			markerLagSeconds.WithLabelValues(*file.LogFileName).Set(time.Since(time.UnixMilli(*file.LastWritten)).Seconds())
+		}
 
-				if !*portion.AdditionalDataPending {
-					break
-				}
+		gotData := false
+		downloadLogFiles(ctx, client, instance, output.DescribeDBLogFiles, engine, *concurrency, markers, aggregator == nil, func(rec *LogRecord) {
+			gotData = true
+			linesEmittedTotal.WithLabelValues(rec.Engine, rec.Severity).Inc()
+			if aggregator != nil {
+				addSlowQuery(aggregator, rec, filterTime, *since != "")
+				return
 			}
+			emitToSink(ctx, sink, rec, filterTime, *since != "")
+		})
+		scheduler.observe(gotData)
 
-			if lastMarker != "" {
-				lastMarkers[*file.LogFileName] = lastMarker
+		if aggregator != nil {
+			if !*follow || time.Since(lastSummaryPrinted) >= *summaryInterval {
+				PrintSummary(os.Stdout, aggregator.TopN(*summaryTop, *summarySort))
+				lastSummaryPrinted = time.Now()
 			}
+		} else if err := sink.Flush(); err != nil {
+			fmt.Printf("Failed to flush sink: %v\n", err)
 		}
 
 		if !*follow {
 			break
 		}
 
-		time.Sleep(5 * time.Second)
+		time.Sleep(scheduler.next())
+	}
+}
+
+// emitToSink writes rec to sink, applying the --since cutoff. It's a no-op
+// if rec is nil (e.g. a parser with nothing buffered to flush).
+func emitToSink(ctx context.Context, sink Sink, rec *LogRecord, filterTime time.Time, sinceSet bool) {
+	if rec == nil || !lineAfter(rec, filterTime, sinceSet) {
+		return
+	}
+	if err := sink.Write(ctx, []*LogRecord{rec}); err != nil {
+		fmt.Printf("Failed to write to sink: %v\n", err)
+	}
+}
+
+// addSlowQuery feeds rec into the aggregator if it's a completed slow-query
+// entry at or after the --since cutoff. It's a no-op if rec is nil or isn't
+// a slow-query entry (e.g. a general/error log line).
+func addSlowQuery(aggregator *SlowLogAggregator, rec *LogRecord, filterTime time.Time, sinceSet bool) {
+	if rec == nil || !lineAfter(rec, filterTime, sinceSet) {
+		return
+	}
+	if event, ok := slowQueryEventFromRecord(rec); ok {
+		aggregator.Add(event)
 	}
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	portionsDownloadedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rdslogs_portions_downloaded_total",
+		Help: "Number of log file portions downloaded from RDS.",
+	}, []string{"file"})
+
+	bytesDownloadedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rdslogs_bytes_downloaded_total",
+		Help: "Bytes of raw log data downloaded from RDS.",
+	}, []string{"file"})
+
+	linesEmittedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rdslogs_lines_emitted_total",
+		Help: "Number of parsed log records emitted to the configured sink.",
+	}, []string{"engine", "severity"})
+
+	downloadErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rdslogs_download_errors_total",
+		Help: "Number of errors encountered calling the RDS API, by error code.",
+	}, []string{"code"})
+
+	markerLagSeconds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rdslogs_marker_lag_seconds",
+		Help: "Seconds between now and the last-written timestamp of each log file.",
+	}, []string{"file"})
+
+	awsAPICallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rdslogs_aws_api_calls_total",
+		Help: "Number of calls made to each RDS API operation.",
+	}, []string{"operation"})
+
+	portionDownloadLatencySeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "rdslogs_portion_download_latency_seconds",
+		Help:    "Latency of DownloadDBLogFilePortion calls.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+// errorCode extracts the AWS error code from err, or "unknown" if err isn't
+// an API error (e.g. a network failure).
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return "unknown"
+}
+
+// healthTracker backs the /healthz endpoint: it reports unhealthy once the
+// last successful DescribeDBLogFiles poll is more than 2x pollInterval old,
+// which is the signal that rdslogs has stopped making progress.
+type healthTracker struct {
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	lastPoll time.Time
+}
+
+func newHealthTracker(pollInterval time.Duration) *healthTracker {
+	return &healthTracker{pollInterval: pollInterval}
+}
+
+func (h *healthTracker) recordPollSuccess() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastPoll = time.Now()
+}
+
+func (h *healthTracker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	last := h.lastPoll
+	h.mu.Unlock()
+
+	if last.IsZero() || time.Since(last) > 2*h.pollInterval {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "unhealthy: last successful DescribeDBLogFiles was %s ago\n", time.Since(last).Round(time.Second))
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// startMetricsServer exposes Prometheus metrics on /metrics and the
+// healthTracker on /healthz at addr. It's meant for long-running follow
+// mode (-f), so rdslogs can operate as a sidecar rather than an ad-hoc CLI.
+func startMetricsServer(addr string, health *healthTracker) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", health)
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Printf("Metrics server on %s exited: %v\n", addr, err)
+		}
+	}()
+}
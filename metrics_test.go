@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestErrorCode(t *testing.T) {
+	apiErr := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "slow down"}
+	if got := errorCode(apiErr); got != "ThrottlingException" {
+		t.Errorf("errorCode(apiErr) = %q, want %q", got, "ThrottlingException")
+	}
+
+	if got := errorCode(errors.New("boom")); got != "unknown" {
+		t.Errorf("errorCode(plain error) = %q, want %q", got, "unknown")
+	}
+}
+
+func TestHealthTrackerServeHTTP(t *testing.T) {
+	h := newHealthTracker(10 * time.Second)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("before any poll, status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	h.recordPollSuccess()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("right after a successful poll, status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	h.mu.Lock()
+	h.lastPoll = time.Now().Add(-3 * h.pollInterval)
+	h.mu.Unlock()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("after a poll older than 2x pollInterval, status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// pollScheduler implements the adaptive follow-mode polling interval:
+// consecutive empty polls double the interval up to max; any poll that
+// turns up new data resets it to min. This keeps idle instances from
+// hammering the RDS API while still reacting quickly once logs start
+// flowing again.
+type pollScheduler struct {
+	min, max time.Duration
+	current  time.Duration
+}
+
+func newPollScheduler(min, max time.Duration) *pollScheduler {
+	return &pollScheduler{min: min, max: max, current: min}
+}
+
+// observe updates the interval based on whether the most recent poll
+// returned any new records.
+func (p *pollScheduler) observe(gotData bool) {
+	if gotData {
+		p.current = p.min
+		return
+	}
+	p.current *= 2
+	if p.current > p.max {
+		p.current = p.max
+	}
+}
+
+// next returns the interval to sleep before the next poll, with up to
+// +/-20% jitter so multiple rdslogs instances polling the same account
+// don't synchronize and collide on RDS API throttling limits.
+func (p *pollScheduler) next() time.Duration {
+	return withJitter(p.current)
+}
+
+func withJitter(d time.Duration) time.Duration {
+	jitter := 0.8 + rand.Float64()*0.4 // +/-20%
+	return time.Duration(float64(d) * jitter)
+}
+
+// isThrottled reports whether err is an AWS throttling response
+// (ThrottlingException / RequestLimitExceeded), which rdslogs should back
+// off and retry rather than treat as fatal.
+func isThrottled(err error) bool {
+	switch errorCode(err) {
+	case "ThrottlingException", "RequestLimitExceeded", "Throttling":
+		return true
+	default:
+		return false
+	}
+}
+
+// retryOnThrottle calls fn, retrying with exponential backoff and jitter
+// while it fails with a throttling error, instead of giving up immediately.
+// It returns the first non-throttling error, or nil once fn succeeds.
+func retryOnThrottle(fn func() error) error {
+	backoff := time.Second
+	const maxBackoff = 30 * time.Second
+
+	for {
+		err := fn()
+		if err == nil || !isThrottled(err) {
+			return err
+		}
+
+		fmt.Printf("Throttled by AWS API (%v), retrying in %s\n", err, backoff)
+		time.Sleep(withJitter(backoff))
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollSchedulerBackoffAndReset(t *testing.T) {
+	min := time.Second
+	max := 8 * time.Second
+	p := newPollScheduler(min, max)
+
+	if p.current != min {
+		t.Fatalf("initial current = %v, want %v", p.current, min)
+	}
+
+	p.observe(false)
+	if p.current != 2*time.Second {
+		t.Errorf("after 1 empty poll, current = %v, want %v", p.current, 2*time.Second)
+	}
+	p.observe(false)
+	if p.current != 4*time.Second {
+		t.Errorf("after 2 empty polls, current = %v, want %v", p.current, 4*time.Second)
+	}
+	p.observe(false)
+	if p.current != max {
+		t.Errorf("after 3 empty polls, current = %v, want it capped at %v", p.current, max)
+	}
+	p.observe(false)
+	if p.current != max {
+		t.Errorf("current should stay capped at %v, got %v", max, p.current)
+	}
+
+	p.observe(true)
+	if p.current != min {
+		t.Errorf("after a poll with data, current = %v, want reset to %v", p.current, min)
+	}
+}
+
+func TestWithJitterStaysWithinTwentyPercent(t *testing.T) {
+	d := 10 * time.Second
+	lo := 8 * time.Second
+	hi := 12 * time.Second
+
+	for i := 0; i < 1000; i++ {
+		got := withJitter(d)
+		if got < lo || got > hi {
+			t.Fatalf("withJitter(%v) = %v, want within [%v, %v]", d, got, lo, hi)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Sink is the destination parsed log records are delivered to. Routing
+// output through a Sink (rather than hard-coding fmt.Print) is what lets
+// rdslogs act as a general-purpose RDS log shipper instead of only
+// streaming to stdout.
+type Sink interface {
+	Write(ctx context.Context, records []*LogRecord) error
+	Flush() error
+	Close() error
+}
+
+// NewSink parses the --sink flag and returns the matching Sink. Supported
+// forms are "stdout" (the default), "file://path", "s3://bucket/prefix",
+// "cloudwatch://group/stream", and "kafka://broker/topic".
+func NewSink(raw string, formatter Formatter) (Sink, error) {
+	if raw == "" || raw == "stdout" {
+		return &stdoutSink{formatter: formatter}, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --sink: %v", err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		path := u.Opaque
+		if path == "" {
+			// url.Parse puts the first path segment after "file://" into
+			// Host (e.g. "file://path" -> Host="path", Path=""), so both
+			// parts need to be rejoined to recover the intended path.
+			path = u.Host + u.Path
+		}
+		return newFileSink(path, formatter, u.Query())
+	case "s3":
+		return newS3Sink(u.Host, strings.TrimPrefix(u.Path, "/"))
+	case "cloudwatch":
+		stream := strings.TrimPrefix(u.Path, "/")
+		return newCloudWatchSink(u.Host, stream)
+	case "kafka":
+		topic := strings.TrimPrefix(u.Path, "/")
+		return newKafkaSink(u.Host, topic)
+	default:
+		return nil, fmt.Errorf("unsupported sink scheme: %s", u.Scheme)
+	}
+}
+
+// stdoutSink reproduces rdslogs' original behaviour of formatting each
+// record straight to standard output.
+type stdoutSink struct {
+	formatter Formatter
+}
+
+func (s *stdoutSink) Write(_ context.Context, records []*LogRecord) error {
+	for _, rec := range records {
+		if err := s.formatter.Format(os.Stdout, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stdoutSink) Flush() error { return nil }
+func (s *stdoutSink) Close() error { return nil }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// cloudWatchSink writes records as CloudWatch Logs events via
+// PutLogEvents, threading the sequence token CloudWatch requires between
+// calls to the same stream.
+type cloudWatchSink struct {
+	group     string
+	stream    string
+	client    *cloudwatchlogs.Client
+	formatter Formatter
+
+	mu            sync.Mutex
+	sequenceToken *string
+	batch         []*LogRecord
+}
+
+func newCloudWatchSink(group, stream string) (*cloudWatchSink, error) {
+	if group == "" || stream == "" {
+		return nil, fmt.Errorf("cloudwatch sink requires both a log group and stream, got group=%q stream=%q", group, stream)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config for cloudwatch sink: %v", err)
+	}
+
+	return &cloudWatchSink{
+		group:     group,
+		stream:    stream,
+		client:    cloudwatchlogs.NewFromConfig(cfg),
+		formatter: jsonFormatter{},
+	}, nil
+}
+
+func (s *cloudWatchSink) Write(_ context.Context, records []*LogRecord) error {
+	s.mu.Lock()
+	s.batch = append(s.batch, records...)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *cloudWatchSink) Flush() error {
+	s.mu.Lock()
+	batch := s.batch
+	s.batch = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	events := make([]types.InputLogEvent, 0, len(batch))
+	for _, rec := range batch {
+		var buf bytes.Buffer
+		if err := s.formatter.Format(&buf, rec); err != nil {
+			return fmt.Errorf("failed to encode record for cloudwatch sink: %v", err)
+		}
+		ts := rec.Timestamp
+		if ts.IsZero() {
+			continue
+		}
+		events = append(events, types.InputLogEvent{
+			Message:   aws.String(buf.String()),
+			Timestamp: aws.Int64(ts.UnixMilli()),
+		})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	// PutLogEvents requires events in a single call to be chronologically
+	// ordered; with concurrent downloads, batch is filled in whatever order
+	// workers for different files deliver records, so it isn't already sorted.
+	sort.Slice(events, func(i, j int) bool {
+		return *events[i].Timestamp < *events[j].Timestamp
+	})
+
+	return s.putLogEvents(events, true)
+}
+
+func (s *cloudWatchSink) putLogEvents(events []types.InputLogEvent, retryOnBadToken bool) error {
+	input := &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(s.group),
+		LogStreamName: aws.String(s.stream),
+		LogEvents:     events,
+		SequenceToken: s.sequenceToken,
+	}
+
+	output, err := s.client.PutLogEvents(context.Background(), input)
+	if err != nil {
+		var badToken *types.InvalidSequenceTokenException
+		if retryOnBadToken && errors.As(err, &badToken) {
+			s.sequenceToken = badToken.ExpectedSequenceToken
+			return s.putLogEvents(events, false)
+		}
+		return fmt.Errorf("failed to put log events to %s/%s: %v", s.group, s.stream, err)
+	}
+
+	s.sequenceToken = output.NextSequenceToken
+	return nil
+}
+
+func (s *cloudWatchSink) Close() error {
+	return s.Flush()
+}
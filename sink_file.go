@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFileSinkMaxSize = 100 * 1024 * 1024 // 100MB
+	defaultFileSinkMaxAge  = 24 * time.Hour
+)
+
+// fileSink writes formatted records to a local file, rotating it to
+// "<path>.<timestamp>" once it grows past maxSize or has been open longer
+// than maxAge.
+type fileSink struct {
+	path      string
+	formatter Formatter
+	maxSize   int64
+	maxAge    time.Duration
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// newFileSink opens (or creates) path for appending. Rotation thresholds
+// can be overridden via the sink URI's query string, e.g.
+// file:///var/log/rdslogs.log?max-size-bytes=52428800&max-age=1h.
+func newFileSink(path string, formatter Formatter, query url.Values) (*fileSink, error) {
+	s := &fileSink{
+		path:      path,
+		formatter: formatter,
+		maxSize:   defaultFileSinkMaxSize,
+		maxAge:    defaultFileSinkMaxAge,
+	}
+
+	if v := query.Get("max-size-bytes"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max-size-bytes: %v", err)
+		}
+		s.maxSize = n
+	}
+	if v := query.Get("max-age"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max-age: %v", err)
+		}
+		s.maxAge = d
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file sink %q: %v", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat file sink %q: %v", s.path, err)
+	}
+
+	s.file = f
+	s.size = info.Size()
+	s.openedAt = time.Now()
+	return nil
+}
+
+func (s *fileSink) rotateIfNeeded() error {
+	if s.size < s.maxSize && time.Since(s.openedAt) < s.maxAge {
+		return nil
+	}
+
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	rotated := fmt.Sprintf("%s.%s", s.path, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.Rename(s.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate file sink %q: %v", s.path, err)
+	}
+	return s.open()
+}
+
+func (s *fileSink) Write(_ context.Context, records []*LogRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rec := range records {
+		if err := s.rotateIfNeeded(); err != nil {
+			return err
+		}
+
+		before := s.size
+		countingWriter := &byteCountWriter{w: s.file}
+		if err := s.formatter.Format(countingWriter, rec); err != nil {
+			return err
+		}
+		s.size = before + countingWriter.n
+	}
+	return nil
+}
+
+func (s *fileSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Sync()
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// byteCountWriter wraps an io.Writer to track bytes written, so fileSink
+// can decide when to rotate without a second Stat call per record.
+type byteCountWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (b *byteCountWriter) Write(p []byte) (int, error) {
+	n, err := b.w.Write(p)
+	b.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// kafkaSink publishes records to a Kafka topic, keying each message by its
+// source log file name so all entries from the same file land on the same
+// partition and preserve per-file ordering for consumers.
+type kafkaSink struct {
+	topic     string
+	writer    *kafka.Writer
+	formatter Formatter
+}
+
+func newKafkaSink(broker, topic string) (*kafkaSink, error) {
+	if broker == "" || topic == "" {
+		return nil, fmt.Errorf("kafka sink requires both a broker and topic, got broker=%q topic=%q", broker, topic)
+	}
+
+	return &kafkaSink{
+		topic: topic,
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(broker),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		},
+		formatter: jsonFormatter{},
+	}, nil
+}
+
+func (s *kafkaSink) Write(ctx context.Context, records []*LogRecord) error {
+	messages := make([]kafka.Message, 0, len(records))
+	for _, rec := range records {
+		var buf bytes.Buffer
+		if err := s.formatter.Format(&buf, rec); err != nil {
+			return fmt.Errorf("failed to encode record for kafka sink: %v", err)
+		}
+		messages = append(messages, kafka.Message{
+			Key:   []byte(rec.File),
+			Value: buf.Bytes(),
+		})
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if err := s.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to write to kafka topic %s: %v", s.topic, err)
+	}
+	return nil
+}
+
+func (s *kafkaSink) Flush() error { return nil }
+
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3Sink batches records in memory and flushes them as gzip'd
+// newline-delimited JSON objects, partitioned by engine and hour so
+// downstream tools (Athena, Spark, ...) can prune by time range.
+type s3Sink struct {
+	bucket    string
+	prefix    string
+	client    *s3.Client
+	formatter Formatter
+
+	mu    sync.Mutex
+	batch []*LogRecord
+}
+
+func newS3Sink(bucket, prefix string) (*s3Sink, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 sink requires a bucket, got %q", "s3://"+bucket+"/"+prefix)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config for s3 sink: %v", err)
+	}
+
+	return &s3Sink{
+		bucket:    bucket,
+		prefix:    prefix,
+		client:    s3.NewFromConfig(cfg),
+		formatter: jsonFormatter{},
+	}, nil
+}
+
+func (s *s3Sink) Write(ctx context.Context, records []*LogRecord) error {
+	s.mu.Lock()
+	s.batch = append(s.batch, records...)
+	s.mu.Unlock()
+	return nil
+}
+
+// Flush groups the pending batch by engine+hour and uploads one gzip'd
+// NDJSON object per group to
+// <prefix>/engine=<engine>/date=<date>/hour=<hour>/<uuid>.json.gz.
+func (s *s3Sink) Flush() error {
+	s.mu.Lock()
+	batch := s.batch
+	s.batch = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	groups := make(map[string][]*LogRecord)
+	for _, rec := range batch {
+		groups[s3PartitionKey(rec)] = append(groups[s3PartitionKey(rec)], rec)
+	}
+
+	for partition, recs := range groups {
+		if err := s.uploadPartition(partition, recs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func s3PartitionKey(rec *LogRecord) string {
+	ts := rec.Timestamp
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+	return fmt.Sprintf("engine=%s/date=%s/hour=%s", rec.Engine, ts.UTC().Format("2006-01-02"), ts.UTC().Format("15"))
+}
+
+func (s *s3Sink) uploadPartition(partition string, recs []*LogRecord) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, rec := range recs {
+		if err := s.formatter.Format(gz, rec); err != nil {
+			return fmt.Errorf("failed to encode record for s3 sink: %v", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to gzip s3 sink batch: %v", err)
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%s/%s.json.gz", s.prefix, partition, id)
+
+	_, err = s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(buf.Bytes()),
+		ContentEncoding: aws.String("gzip"),
+		ContentType:     aws.String("application/x-ndjson"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to s3://%s: %v", key, s.bucket, err)
+	}
+	return nil
+}
+
+func (s *s3Sink) Close() error {
+	return s.Flush()
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate object id: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestS3PartitionKey(t *testing.T) {
+	rec := &LogRecord{
+		Engine:    "mysql",
+		Timestamp: time.Date(2023, 6, 15, 14, 30, 0, 0, time.UTC),
+	}
+	want := "engine=mysql/date=2023-06-15/hour=14"
+	if got := s3PartitionKey(rec); got != want {
+		t.Errorf("s3PartitionKey = %q, want %q", got, want)
+	}
+}
+
+func TestS3PartitionKeyFallsBackToNowForZeroTimestamp(t *testing.T) {
+	rec := &LogRecord{Engine: "postgres"}
+	got := s3PartitionKey(rec)
+	want := "engine=postgres/date=" + time.Now().UTC().Format("2006-01-02") + "/hour="
+	if len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("s3PartitionKey (zero timestamp) = %q, want prefix %q", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSinkFileURL(t *testing.T) {
+	// "file://rdslogs.log" (the documented --sink form) puts "rdslogs.log"
+	// into url.URL.Host rather than Path, so NewSink must rejoin Host+Path
+	// to recover it; "file:///abs/path" (Host empty, Path absolute) must
+	// keep working too.
+	t.Chdir(t.TempDir())
+	absPath := filepath.Join(t.TempDir(), "rdslogs.log")
+
+	cases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"documented double-slash form", "file://rdslogs.log", "rdslogs.log"},
+		{"triple-slash absolute form", "file://" + absPath, absPath},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sink, err := NewSink(c.raw, textFormatter{})
+			if err != nil {
+				t.Fatalf("NewSink(%q): %v", c.raw, err)
+			}
+			defer sink.Close()
+
+			fs, ok := sink.(*fileSink)
+			if !ok {
+				t.Fatalf("NewSink(%q) returned %T, want *fileSink", c.raw, sink)
+			}
+			if fs.path != c.want {
+				t.Errorf("path = %q, want %q", fs.path, c.want)
+			}
+		})
+	}
+}
+
+func TestNewSinkStdoutDefault(t *testing.T) {
+	for _, raw := range []string{"", "stdout"} {
+		sink, err := NewSink(raw, textFormatter{})
+		if err != nil {
+			t.Fatalf("NewSink(%q): %v", raw, err)
+		}
+		if _, ok := sink.(*stdoutSink); !ok {
+			t.Errorf("NewSink(%q) returned %T, want *stdoutSink", raw, sink)
+		}
+	}
+}
+
+func TestNewSinkUnsupportedScheme(t *testing.T) {
+	if _, err := NewSink("ftp://example.com/path", textFormatter{}); err == nil {
+		t.Error("NewSink with an unsupported scheme should return an error")
+	}
+}
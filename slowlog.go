@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// SlowQueryEvent is a single entry from a MySQL/MariaDB slow query log,
+// extracted from the "# Time:"/"# User@Host:"/"# Query_time:" block plus
+// the SQL statement that follows it.
+type SlowQueryEvent struct {
+	User         string
+	Host         string
+	QueryTime    float64
+	LockTime     float64
+	RowsSent     int
+	RowsExamined int
+	SQL          string
+}
+
+// slowQueryEventFromRecord builds a SlowQueryEvent from a LogRecord produced
+// by the MySQL LogParser, which already extracts the slow-query fields into
+// rec.Fields. It returns false if rec isn't a slow-query entry.
+func slowQueryEventFromRecord(rec *LogRecord) (*SlowQueryEvent, bool) {
+	queryTime, ok := rec.Fields["query_time"]
+	if !ok {
+		return nil, false
+	}
+
+	event := &SlowQueryEvent{SQL: rec.Message}
+	event.QueryTime, _ = strconv.ParseFloat(queryTime, 64)
+	event.LockTime, _ = strconv.ParseFloat(rec.Fields["lock_time"], 64)
+	event.RowsSent, _ = strconv.Atoi(rec.Fields["rows_sent"])
+	event.RowsExamined, _ = strconv.Atoi(rec.Fields["rows_examined"])
+
+	// user_host looks like "user[user] @ host [ip]", so split on "@" first to
+	// separate the user and host segments, then strip each segment's own
+	// bracketed part ("[user]" / "[ip]") to get the bare name.
+	if userHost := rec.Fields["user_host"]; userHost != "" {
+		if userPart, hostPart, found := strings.Cut(userHost, "@"); found {
+			user, _, _ := strings.Cut(userPart, "[")
+			event.User = strings.TrimSpace(user)
+			host, _, _ := strings.Cut(strings.TrimSpace(hostPart), "[")
+			event.Host = strings.TrimSpace(host)
+		}
+	}
+
+	return event, true
+}
+
+var (
+	fingerprintStringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.)*'`)
+	fingerprintNumber        = regexp.MustCompile(`\b\d+\b`)
+	fingerprintInList        = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	fingerprintWhitespace    = regexp.MustCompile(`\s+`)
+)
+
+// fingerprintSQL normalizes a SQL statement into a query "shape" by
+// replacing literals, IN-lists, and incidental whitespace with placeholders,
+// so that structurally identical queries aggregate together regardless of
+// their bound values.
+func fingerprintSQL(sql string) string {
+	fp := fingerprintStringLiteral.ReplaceAllString(sql, "?")
+	fp = fingerprintNumber.ReplaceAllString(fp, "?")
+	fp = fingerprintInList.ReplaceAllString(fp, "(...)")
+	fp = fingerprintWhitespace.ReplaceAllString(fp, " ")
+	return strings.TrimSpace(fp)
+}
+
+// slowLogStat aggregates every SlowQueryEvent seen for a given fingerprint.
+type slowLogStat struct {
+	Fingerprint      string
+	Count            int
+	TotalQueryTime   float64
+	MinQueryTime     float64
+	MaxQueryTime     float64
+	TotalRowsExam    int64
+	queryTimeSamples []float64
+}
+
+func (s *slowLogStat) p95QueryTime() float64 {
+	if len(s.queryTimeSamples) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), s.queryTimeSamples...)
+	sort.Float64s(sorted)
+	idx := int(float64(len(sorted)-1) * 0.95)
+	return sorted[idx]
+}
+
+// SlowLogAggregator builds a top-N report of MySQL slow query fingerprints,
+// similar in spirit to pt-query-digest.
+type SlowLogAggregator struct {
+	stats map[string]*slowLogStat
+}
+
+// NewSlowLogAggregator returns an empty SlowLogAggregator.
+func NewSlowLogAggregator() *SlowLogAggregator {
+	return &SlowLogAggregator{stats: make(map[string]*slowLogStat)}
+}
+
+// Add folds one SlowQueryEvent into the aggregator.
+func (a *SlowLogAggregator) Add(event *SlowQueryEvent) {
+	fp := fingerprintSQL(event.SQL)
+	stat, ok := a.stats[fp]
+	if !ok {
+		stat = &slowLogStat{Fingerprint: fp, MinQueryTime: event.QueryTime, MaxQueryTime: event.QueryTime}
+		a.stats[fp] = stat
+	}
+
+	stat.Count++
+	stat.TotalQueryTime += event.QueryTime
+	stat.TotalRowsExam += int64(event.RowsExamined)
+	stat.queryTimeSamples = append(stat.queryTimeSamples, event.QueryTime)
+	if event.QueryTime < stat.MinQueryTime {
+		stat.MinQueryTime = event.QueryTime
+	}
+	if event.QueryTime > stat.MaxQueryTime {
+		stat.MaxQueryTime = event.QueryTime
+	}
+}
+
+// TopN returns the n fingerprints with the highest ranking by sortBy
+// ("time", "count", or "rows"), most significant first.
+func (a *SlowLogAggregator) TopN(n int, sortBy string) []*slowLogStat {
+	stats := make([]*slowLogStat, 0, len(a.stats))
+	for _, stat := range a.stats {
+		stats = append(stats, stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		switch sortBy {
+		case "count":
+			return stats[i].Count > stats[j].Count
+		case "rows":
+			return stats[i].TotalRowsExam > stats[j].TotalRowsExam
+		default:
+			return stats[i].TotalQueryTime > stats[j].TotalQueryTime
+		}
+	})
+
+	if n > 0 && len(stats) > n {
+		stats = stats[:n]
+	}
+	return stats
+}
+
+// PrintSummary renders the top query fingerprints as a table.
+func PrintSummary(w io.Writer, stats []*slowLogStat) {
+	tw := tabwriter.NewWriter(w, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "COUNT\tTOTAL_TIME\tAVG_TIME\tMAX_TIME\tP95_TIME\tROWS_EXAMINED\tQUERY")
+	for _, stat := range stats {
+		avg := stat.TotalQueryTime / float64(stat.Count)
+		fmt.Fprintf(tw, "%d\t%.3f\t%.3f\t%.3f\t%.3f\t%d\t%s\n",
+			stat.Count, stat.TotalQueryTime, avg, stat.MaxQueryTime, stat.p95QueryTime(), stat.TotalRowsExam, stat.Fingerprint)
+	}
+	tw.Flush()
+}
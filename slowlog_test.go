@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestFingerprintSQL(t *testing.T) {
+	cases := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT * FROM users WHERE name = 'bob'", "SELECT * FROM users WHERE name = ?"},
+		{"SELECT * FROM t WHERE id IN (1, 2, 3)", "SELECT * FROM t WHERE id IN (...)"},
+		{"SELECT   *   FROM t", "SELECT * FROM t"},
+	}
+	for _, c := range cases {
+		if got := fingerprintSQL(c.sql); got != c.want {
+			t.Errorf("fingerprintSQL(%q) = %q, want %q", c.sql, got, c.want)
+		}
+	}
+}
+
+func TestSlowQueryEventFromRecordHost(t *testing.T) {
+	rec := &LogRecord{
+		Message: "SELECT 1",
+		Fields: map[string]string{
+			"query_time":    "1.5",
+			"lock_time":     "0.0",
+			"rows_sent":     "1",
+			"rows_examined": "1",
+			"user_host":     "root[root] @  localhost []",
+		},
+	}
+
+	event, ok := slowQueryEventFromRecord(rec)
+	if !ok {
+		t.Fatal("slowQueryEventFromRecord returned ok=false")
+	}
+	if event.User != "root" {
+		t.Errorf("User = %q, want %q", event.User, "root")
+	}
+	if event.Host != "localhost" {
+		t.Errorf("Host = %q, want %q", event.Host, "localhost")
+	}
+}
+
+func TestSlowLogAggregatorTopN(t *testing.T) {
+	a := NewSlowLogAggregator()
+	a.Add(&SlowQueryEvent{SQL: "SELECT * FROM a WHERE id = 1", QueryTime: 1, RowsExamined: 10})
+	a.Add(&SlowQueryEvent{SQL: "SELECT * FROM a WHERE id = 2", QueryTime: 3, RowsExamined: 20})
+	a.Add(&SlowQueryEvent{SQL: "SELECT * FROM b WHERE id = 1", QueryTime: 2, RowsExamined: 100})
+
+	byTime := a.TopN(2, "time")
+	if len(byTime) != 2 {
+		t.Fatalf("len(byTime) = %d, want 2", len(byTime))
+	}
+	if byTime[0].Fingerprint != "SELECT * FROM a WHERE id = ?" || byTime[0].Count != 2 {
+		t.Errorf("top time entry = %+v", byTime[0])
+	}
+
+	byRows := a.TopN(1, "rows")
+	if len(byRows) != 1 || byRows[0].Fingerprint != "SELECT * FROM b WHERE id = ?" {
+		t.Errorf("top rows entry = %+v", byRows)
+	}
+}
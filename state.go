@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// markerStore is a concurrency-safe map of log file name -> last-read
+// marker. When a state file path is set, every update is persisted
+// immediately, so restarting `rdslogs -f --state-file=...` resumes exactly
+// where it left off instead of re-downloading or missing data.
+type markerStore struct {
+	mu        sync.Mutex
+	markers   map[string]string
+	stateFile string
+
+	persistMu sync.Mutex
+}
+
+// newMarkerStore creates a markerStore, loading any markers previously
+// persisted to stateFile. An empty stateFile disables persistence.
+func newMarkerStore(stateFile string) (*markerStore, error) {
+	s := &markerStore{markers: make(map[string]string), stateFile: stateFile}
+	if stateFile == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %v", err)
+	}
+	if err := json.Unmarshal(data, &s.markers); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %v", err)
+	}
+	return s, nil
+}
+
+// Get returns the last-read marker for file, or "" if there isn't one.
+func (s *markerStore) Get(file string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.markers[file]
+}
+
+// Set records the marker for file and, if a state file is configured,
+// persists the whole store to disk.
+func (s *markerStore) Set(file, marker string) {
+	s.mu.Lock()
+	s.markers[file] = marker
+	s.mu.Unlock()
+
+	if s.stateFile == "" {
+		return
+	}
+	if err := s.persist(); err != nil {
+		fmt.Printf("Failed to persist state file: %v\n", err)
+	}
+}
+
+// persist writes the current markers to s.stateFile atomically via a temp
+// file + rename, so a crash mid-write can't leave behind a truncated
+// checkpoint. persistMu serializes concurrent callers (e.g. one per
+// --concurrency worker), both so they don't race on the shared tmp path and
+// so an older snapshot can't clobber a newer one written by a writer that
+// got there first; the snapshot is taken fresh under persistMu, not at the
+// time Set was called, so the most recent write always wins.
+func (s *markerStore) persist() error {
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := make(map[string]string, len(s.markers))
+	for k, v := range s.markers {
+		snapshot[k] = v
+	}
+	s.mu.Unlock()
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.stateFile)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestMarkerStoreRoundTrip(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := newMarkerStore(stateFile)
+	if err != nil {
+		t.Fatalf("newMarkerStore: %v", err)
+	}
+	s.Set("error/mysql-error.log", "0:1234")
+	s.Set("slowquery/mysql-slowquery.log", "0:5678")
+
+	reloaded, err := newMarkerStore(stateFile)
+	if err != nil {
+		t.Fatalf("newMarkerStore (reload): %v", err)
+	}
+	if got := reloaded.Get("error/mysql-error.log"); got != "0:1234" {
+		t.Errorf("Get(error log) = %q, want %q", got, "0:1234")
+	}
+	if got := reloaded.Get("slowquery/mysql-slowquery.log"); got != "0:5678" {
+		t.Errorf("Get(slowquery log) = %q, want %q", got, "0:5678")
+	}
+}
+
+func TestMarkerStoreWithoutStateFile(t *testing.T) {
+	s, err := newMarkerStore("")
+	if err != nil {
+		t.Fatalf("newMarkerStore: %v", err)
+	}
+	s.Set("foo", "bar")
+	if got := s.Get("foo"); got != "bar" {
+		t.Errorf("Get(foo) = %q, want %q", got, "bar")
+	}
+}
+
+func TestMarkerStoreConcurrentSetDoesNotRace(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	s, err := newMarkerStore(stateFile)
+	if err != nil {
+		t.Fatalf("newMarkerStore: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Set(fmt.Sprintf("file-%d", i), fmt.Sprintf("marker-%d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	reloaded, err := newMarkerStore(stateFile)
+	if err != nil {
+		t.Fatalf("newMarkerStore (reload): %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		want := fmt.Sprintf("marker-%d", i)
+		if got := reloaded.Get(fmt.Sprintf("file-%d", i)); got != want {
+			t.Errorf("Get(file-%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestNewMarkerStoreMissingFileIsNotAnError(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "does-not-exist.json")
+	s, err := newMarkerStore(stateFile)
+	if err != nil {
+		t.Fatalf("newMarkerStore: %v", err)
+	}
+	if got := s.Get("foo"); got != "" {
+		t.Errorf("Get(foo) = %q, want empty", got)
+	}
+}
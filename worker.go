@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+// logBatch is one downloaded portion's worth of parsed records from a
+// single log file, tagged with the file name and portion sequence number
+// so the fan-in writer's output can be traced back to its source.
+type logBatch struct {
+	file    string
+	seq     int
+	records []*LogRecord
+}
+
+// downloadLogFiles fans files out across a pool of up to concurrency
+// workers. Each file is handled start-to-finish by a single worker (with
+// its own LogParser instance), which preserves per-file ordering; only the
+// interleaving of output between different files is affected by
+// concurrency. Records are funneled through one fan-in loop that calls
+// handle in the order batches arrive, so handle itself needs no locking.
+func downloadLogFiles(ctx context.Context, client *rds.Client, instance *string, files []types.DescribeDBLogFilesDetails, engine string, concurrency int, markers *markerStore, printUnparsed bool, handle func(*LogRecord)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan types.DescribeDBLogFilesDetails)
+	results := make(chan logBatch)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				downloadLogFile(ctx, client, instance, file, engine, markers, printUnparsed, results)
+			}
+		}()
+	}
+
+	go func() {
+		for _, file := range files {
+			jobs <- file
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	for batch := range results {
+		for _, rec := range batch.records {
+			handle(rec)
+		}
+	}
+}
+
+// downloadLogFile downloads every available portion of one log file in
+// order, parsing each with a dedicated LogParser so concurrent workers
+// never share parser state, and emits one logBatch per portion plus a
+// final one for any buffered multi-line record.
+func downloadLogFile(ctx context.Context, client *rds.Client, instance *string, file types.DescribeDBLogFilesDetails, engine string, markers *markerStore, printUnparsed bool, results chan<- logBatch) {
+	parser, err := NewLogParser(engine)
+	if err != nil {
+		fmt.Printf("Unsupported engine: %v\n", err)
+		return
+	}
+
+	downloadInput := &rds.DownloadDBLogFilePortionInput{
+		DBInstanceIdentifier: instance,
+		LogFileName:          file.LogFileName,
+	}
+	if marker := markers.Get(*file.LogFileName); marker != "" {
+		downloadInput.Marker = &marker
+	}
+
+	var lastMarker string
+	seq := 0
+	for {
+		var portion *rds.DownloadDBLogFilePortionOutput
+		err := retryOnThrottle(func() error {
+			awsAPICallsTotal.WithLabelValues("DownloadDBLogFilePortion").Inc()
+			start := time.Now()
+			var callErr error
+			portion, callErr = client.DownloadDBLogFilePortion(ctx, downloadInput)
+			portionDownloadLatencySeconds.Observe(time.Since(start).Seconds())
+			return callErr
+		})
+		if err != nil {
+			downloadErrorsTotal.WithLabelValues(errorCode(err)).Inc()
+			fmt.Printf("Failed to download log portion: %v\n", err)
+			break
+		}
+		portionsDownloadedTotal.WithLabelValues(*file.LogFileName).Inc()
+
+		var records []*LogRecord
+		if portion.LogFileData != nil {
+			bytesDownloadedTotal.WithLabelValues(*file.LogFileName).Add(float64(len(*portion.LogFileData)))
+			for _, line := range strings.Split(*portion.LogFileData, "\n") {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				rec, err := parser.Feed(line)
+				if err != nil {
+					if printUnparsed {
+						records = append(records, &LogRecord{Message: line, Raw: line, Engine: engine, File: *file.LogFileName})
+					}
+					continue
+				}
+				if rec != nil {
+					rec.File = *file.LogFileName
+					records = append(records, rec)
+				}
+			}
+		}
+		if len(records) > 0 {
+			results <- logBatch{file: *file.LogFileName, seq: seq, records: records}
+			seq++
+		}
+
+		if portion.Marker != nil {
+			lastMarker = *portion.Marker
+			downloadInput.Marker = &lastMarker
+			markers.Set(*file.LogFileName, lastMarker)
+		}
+
+		if !*portion.AdditionalDataPending {
+			break
+		}
+	}
+
+	if rec := parser.Flush(); rec != nil {
+		rec.File = *file.LogFileName
+		results <- logBatch{file: *file.LogFileName, seq: seq, records: []*LogRecord{rec}}
+	}
+}